@@ -1,17 +1,186 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
-	"net/http"
+	"fmt"
 	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/mmcdole/gofeed"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
 )
 
+// pubDateLayouts lists the timestamp formats seen in the wild across RSS,
+// Atom, and WordPress-flavored feeds, tried in order by parsePubDate.
+var pubDateLayouts = []string{
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC822,
+	time.RFC822Z,
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"01/02/2006 15:04:05",
+}
+
+// parsePubDate tries each known layout in turn, then falls back to
+// parsePermissive, and returns the first successful parse. Feeds in the
+// wild mix date formats, so a single time.Parse call (as RSS's own
+// RFC1123 default would require) is too strict for real-world aggregation.
+func parsePubDate(raw string) (time.Time, error) {
+	var lastErr error
+	for _, layout := range pubDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		} else {
+			lastErr = err
+		}
+	}
+	if t, err := parsePermissive(raw); err == nil {
+		return t, nil
+	} else {
+		lastErr = err
+	}
+	return time.Time{}, fmt.Errorf("no matching layout for %q: %w", raw, lastErr)
+}
+
+var monthNames = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// permissiveDatePattern pulls a date (numeric or month-name, in whatever
+// order and with whatever separators the source used), an optional
+// time-of-day, and an optional UTC offset or zone abbreviation out of a
+// pubDate string that didn't match any fixed layout.
+var permissiveDatePattern = regexp.MustCompile(
+	`(?i)(\d{1,4}|[a-z]+)[\s./,-]+(\d{1,4}|[a-z]+)[\s./,-]+(\d{1,4})` +
+		`(?:[\sT]+(\d{1,2}):(\d{2})(?::(\d{2}))?)?` +
+		`\s*([+-]\d{2}:?\d{2}|Z|UTC|GMT)?`,
+)
+
+// parsePermissive is a small dateparse-style fallback: instead of matching
+// a fixed layout, it pulls date/time components out with a regex and
+// disambiguates them by shape (4 digits or >31 is the year; a recognized
+// name or 1-12 is the month; whatever's left is the day), so pubDate
+// strings in formats we haven't seen before still resolve.
+func parsePermissive(raw string) (time.Time, error) {
+	m := permissiveDatePattern.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return time.Time{}, fmt.Errorf("permissive parse: no date found in %q", raw)
+	}
+
+	year, month, day, ok := resolveDateParts(m[1], m[2], m[3])
+	if !ok {
+		return time.Time{}, fmt.Errorf("permissive parse: ambiguous date in %q", raw)
+	}
+
+	hour, min, sec := 0, 0, 0
+	if m[4] != "" {
+		hour, _ = strconv.Atoi(m[4])
+		min, _ = strconv.Atoi(m[5])
+		if m[6] != "" {
+			sec, _ = strconv.Atoi(m[6])
+		}
+	}
+
+	loc, err := resolveZone(m[7])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("permissive parse: %w", err)
+	}
+
+	return time.Date(year, month, day, hour, min, sec, 0, loc), nil
+}
+
+// resolveDateParts disambiguates three date tokens (numeric or a month
+// name, in any order) into a year/month/day triple. Whichever non-year,
+// non-month tokens remain are read left to right as month then day, which
+// covers both ISO (YYYY-MM-DD) and US (MM/DD/YYYY) conventions.
+func resolveDateParts(a, b, c string) (int, time.Month, int, bool) {
+	year, month := 0, time.Month(0)
+	var remaining []string
+
+	for _, tok := range []string{a, b, c} {
+		if m, ok := monthNames[strings.ToLower(tok)]; ok {
+			month = m
+			continue
+		}
+		n, err := strconv.Atoi(tok)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		if year == 0 && (len(tok) == 4 || n > 31) {
+			year = n
+			continue
+		}
+		remaining = append(remaining, tok)
+	}
+	if year == 0 {
+		return 0, 0, 0, false
+	}
+
+	day := 0
+	for _, tok := range remaining {
+		n, _ := strconv.Atoi(tok)
+		if month == 0 {
+			month = time.Month(n)
+		} else {
+			day = n
+		}
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return 0, 0, 0, false
+	}
+	return year, month, day, true
+}
+
+// resolveZone turns the offset captured by permissiveDatePattern (a
+// +hh:mm/-hhmm offset, Z, UTC, GMT, or nothing) into a time.Location,
+// defaulting to UTC when no zone was present.
+func resolveZone(raw string) (*time.Location, error) {
+	switch raw {
+	case "", "Z", "UTC", "GMT":
+		return time.UTC, nil
+	}
+
+	sign := 1
+	if raw[0] == '-' {
+		sign = -1
+	}
+	digits := strings.ReplaceAll(raw[1:], ":", "")
+	hh, err := strconv.Atoi(digits[:2])
+	if err != nil {
+		return nil, fmt.Errorf("bad zone offset %q", raw)
+	}
+	mm, err := strconv.Atoi(digits[2:])
+	if err != nil {
+		return nil, fmt.Errorf("bad zone offset %q", raw)
+	}
+	return time.FixedZone("", sign*(hh*3600+mm*60)), nil
+}
+
 type Location struct {
 	Name string  `json:"name"`
 	Lat  float32 `json:"lat"`
@@ -50,6 +219,200 @@ type NewsData struct {
 
 const targetURL = "https://715814cd2aaf.ngrok-free.app/news_information_in"
 
+// dispatchJob pairs an item with a label identifying where it came from in
+// its source (file slice index, or a running count for live feed polls),
+// plus the checkpoint key used to record its send outcome.
+type dispatchJob struct {
+	index int
+	wrap  int
+	key   string
+	item  NewsItem
+}
+
+// loadNewsData reads and parses the JSON news dump used by replay mode.
+func loadNewsData(filename string) (*NewsData, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	var newsData NewsData
+	if err := json.Unmarshal(data, &newsData); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+	return &newsData, nil
+}
+
+// itemKey returns the identity used to dedupe feed entries across polls and
+// to key checkpoint status, preferring GUID but falling back to Link since
+// not all feeds set a GUID.
+func itemKey(item NewsItem) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	if item.Link != "" {
+		return item.Link
+	}
+	if item.Title != "" || item.PubDate != "" {
+		return fmt.Sprintf("title:%s|%s", item.Title, item.PubDate)
+	}
+	return ""
+}
+
+// fetchFeed pulls a live RSS/Atom/JSON Feed document and maps each entry
+// into a NewsItem, so feeds can be consumed directly without a conversion
+// step to the on-disk NewsData format.
+func fetchFeed(ctx context.Context, feedURL string) (*NewsData, error) {
+	fp := gofeed.NewParser()
+	feed, err := fp.ParseURLWithContext(feedURL, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching feed %s: %w", feedURL, err)
+	}
+
+	newsData := &NewsData{
+		Feed: FeedInfo{
+			Title:       feed.Title,
+			Link:        feed.Link,
+			Description: feed.Description,
+			Language:    feed.Language,
+			Generator:   feed.Generator,
+		},
+	}
+
+	for _, entry := range feed.Items {
+		guid := entry.GUID
+		if guid == "" {
+			guid = entry.Link
+		}
+		newsData.Items = append(newsData.Items, NewsItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			GUID:        guid,
+			PubDate:     entry.Published,
+			Description: entry.Description,
+			Source:      feed.Title,
+		})
+	}
+	newsData.TotalItems = len(newsData.Items)
+	return newsData, nil
+}
+
+// ItemStatus records the outcome of the most recent send attempt for one
+// item, keyed by itemKey (or "idx:N" for replay items without a GUID/Link).
+type ItemStatus struct {
+	Success    bool      `json:"success"`
+	HTTPStatus int       `json:"httpStatus,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	SentAt     time.Time `json:"sentAt"`
+}
+
+// Checkpoint is the on-disk record of replay progress and per-item send
+// status, so a long unattended run can be restarted without resending
+// everything or losing track of what failed. LastIndex is -1 until the
+// first item is confirmed sent, since 0 is a real (and often first) index
+// and can't double as "nothing confirmed yet".
+type Checkpoint struct {
+	LastIndex int                   `json:"lastIndex"`
+	WrapCount int                   `json:"wrapCount"`
+	Items     map[string]ItemStatus `json:"items"`
+}
+
+// checkpointStore guards a Checkpoint with a mutex and flushes it to disk
+// every flushEvery recorded outcomes, plus once more on shutdown.
+type checkpointStore struct {
+	mu         sync.Mutex
+	path       string
+	data       Checkpoint
+	existed    bool
+	flushEvery int
+	sinceFlush int
+}
+
+// loadCheckpoint reads an existing checkpoint file, or returns a fresh,
+// empty one if path is empty or the file doesn't exist yet. existed reports
+// whether a prior checkpoint was actually found, so -resume can fall back
+// to -start on a first run instead of resuming from the zero value.
+func loadCheckpoint(path string) (*checkpointStore, error) {
+	cs := &checkpointStore{path: path, data: Checkpoint{LastIndex: -1, Items: make(map[string]ItemStatus)}}
+	if path == "" {
+		return cs, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cs, nil
+		}
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(raw, &cs.data); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint: %w", err)
+	}
+	if cs.data.Items == nil {
+		cs.data.Items = make(map[string]ItemStatus)
+	}
+	cs.existed = true
+	return cs, nil
+}
+
+// recordStatus stores the send outcome for key, flushing to disk once
+// flushEvery outcomes have accumulated since the last flush. On success it
+// also advances the replay cursor (index, wrap-around count), but only
+// forward: since -workers send concurrently and retry with backoff, a
+// later dispatch can be confirmed before an earlier one, and the recorded
+// cursor must reflect the highest confirmed position, not dispatch order.
+func (cs *checkpointStore) recordStatus(key string, index, wrap int, status ItemStatus) {
+	if cs.path == "" || key == "" {
+		return
+	}
+	cs.mu.Lock()
+	cs.data.Items[key] = status
+	if status.Success && (wrap > cs.data.WrapCount || (wrap == cs.data.WrapCount && index > cs.data.LastIndex)) {
+		cs.data.LastIndex = index
+		cs.data.WrapCount = wrap
+	}
+	cs.sinceFlush++
+	shouldFlush := cs.flushEvery > 0 && cs.sinceFlush >= cs.flushEvery
+	if shouldFlush {
+		cs.sinceFlush = 0
+	}
+	cs.mu.Unlock()
+
+	if shouldFlush {
+		if err := cs.flush(); err != nil {
+			log.Error().Err(err).Str("path", cs.path).Msg("Failed to flush checkpoint")
+		}
+	}
+}
+
+// flush writes the current checkpoint state to disk.
+func (cs *checkpointStore) flush() error {
+	if cs.path == "" {
+		return nil
+	}
+	cs.mu.Lock()
+	raw, err := json.MarshalIndent(cs.data, "", "  ")
+	cs.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshaling checkpoint: %w", err)
+	}
+	return os.WriteFile(cs.path, raw, 0o644)
+}
+
+// failedKeys returns the keys of items whose last recorded attempt failed,
+// for driving a -retry-failed run.
+func (cs *checkpointStore) failedKeys() map[string]struct{} {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	keys := make(map[string]struct{})
+	for key, status := range cs.data.Items {
+		if !status.Success {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}
+
 func main() {
 	// 1. Setup Zerolog with Nano precision timestamp
 	log.Logger = log.Output(zerolog.ConsoleWriter{
@@ -59,130 +422,382 @@ func main() {
 
 	// 2. Define flags
 	var filename string
+	var feedURL string
+	var pollInterval time.Duration
 	var startPercent int
 	var delay int
+	var workers int
+	var rps float64
+	var maxInflight int
+	var statePath string
+	var resume bool
+	var retryFailed bool
+	var checkpointEvery int
+	var maxRetries int
+	var retryBaseMs int
+	var retryMaxMs int
+	var breakerThreshold int
+	var breakerCooldown time.Duration
+	var shutdownGrace time.Duration
+	var sinkKind string
+	var outFile string
+	var kafkaBrokers string
+	var kafkaTopic string
 	flag.StringVar(&filename, "file", "news.json", "JSON file containing news data")
-	flag.IntVar(&startPercent, "start", 0, "Percentage (0-100) to start from in the news list")
-	flag.IntVar(&delay, "delay", 1000, "Delay in milliseconds between each item")
+	flag.StringVar(&feedURL, "feed", "", "RSS/Atom/JSON Feed URL to crawl instead of -file")
+	flag.DurationVar(&pollInterval, "poll", 0, "When using -feed, how often to re-poll for new entries (0 = fetch once)")
+	flag.IntVar(&startPercent, "start", 0, "Percentage (0-100) to start from in the news list (-file mode only)")
+	flag.IntVar(&delay, "delay", 0, "Optional fixed delay in milliseconds between each dispatched item (0 disables)")
+	flag.IntVar(&workers, "workers", 1, "Number of concurrent worker goroutines sending requests")
+	flag.Float64Var(&rps, "rps", 1, "Maximum requests per second across all workers (token-bucket rate limit)")
+	flag.IntVar(&maxInflight, "max-inflight", 4, "Maximum number of requests in flight at once")
+	flag.StringVar(&statePath, "state", "", "Path to a checkpoint file recording send progress and per-item status")
+	flag.BoolVar(&resume, "resume", false, "Resume from the position recorded in -state instead of -start")
+	flag.BoolVar(&retryFailed, "retry-failed", false, "Only replay items recorded as failed in -state")
+	flag.IntVar(&checkpointEvery, "checkpoint-every", 10, "Flush -state to disk after this many recorded outcomes")
+	flag.IntVar(&maxRetries, "max-retries", 3, "Maximum retries per item on network errors or 5xx/429 responses")
+	flag.IntVar(&retryBaseMs, "retry-base", 200, "Base backoff in milliseconds before the first retry")
+	flag.IntVar(&retryMaxMs, "retry-max", 10000, "Maximum backoff in milliseconds between retries")
+	flag.IntVar(&breakerThreshold, "breaker-threshold", 5, "Consecutive send failures before the circuit breaker opens")
+	flag.DurationVar(&breakerCooldown, "breaker-cooldown", 30*time.Second, "How long the circuit breaker stays open once tripped")
+	flag.DurationVar(&shutdownGrace, "shutdown-grace", 20*time.Second, "On SIGINT/SIGTERM, how long to let in-flight sends finish before forcing them to abort")
+	flag.StringVar(&sinkKind, "sink", "http", "Where to deliver items: http, file, stdout, or kafka")
+	flag.StringVar(&outFile, "out-file", "", "NDJSON output path for -sink file")
+	flag.StringVar(&kafkaBrokers, "kafka-brokers", "", "Comma-separated broker addresses for -sink kafka")
+	flag.StringVar(&kafkaTopic, "kafka-topic", "", "Topic name for -sink kafka")
 	flag.Parse()
 
 	// 3. Validate inputs
 	if startPercent < 0 || startPercent > 100 {
 		log.Fatal().Int("start", startPercent).Msg("Start percentage must be between 0 and 100")
 	}
+	if workers < 1 {
+		log.Fatal().Int("workers", workers).Msg("workers must be at least 1")
+	}
+	if maxInflight < 1 {
+		log.Fatal().Int("max-inflight", maxInflight).Msg("max-inflight must be at least 1")
+	}
+	if (resume || retryFailed) && statePath == "" {
+		log.Fatal().Msg("-resume and -retry-failed require -state")
+	}
+	if maxRetries < 0 {
+		log.Fatal().Int("max-retries", maxRetries).Msg("max-retries must be at least 0")
+	}
 
-	// 4. Read File
-	data, err := os.ReadFile(filename)
+	checkpoint, err := loadCheckpoint(statePath)
 	if err != nil {
-		log.Fatal().Err(err).Str("file", filename).Msg("Error reading file")
+		log.Fatal().Err(err).Str("state", statePath).Msg("Failed to load checkpoint")
 	}
+	checkpoint.flushEvery = checkpointEvery
 
-	// 5. Parse JSON
-	var newsData NewsData
-	if err := json.Unmarshal(data, &newsData); err != nil {
-		log.Fatal().Err(err).Msg("Error parsing JSON")
+	retryCfg := retryConfig{
+		MaxRetries: maxRetries,
+		BaseDelay:  time.Duration(retryBaseMs) * time.Millisecond,
+		MaxDelay:   time.Duration(retryMaxMs) * time.Millisecond,
 	}
 
-	totalItems := len(newsData.Items)
-	if totalItems == 0 {
-		log.Fatal().Str("file", filename).Msg("No news items found in file")
+	// 4. Setup the output sink
+	sink, err := newSink(sinkKind, outFile, kafkaBrokers, kafkaTopic, retryCfg, breakerThreshold, breakerCooldown)
+	if err != nil {
+		log.Fatal().Err(err).Str("sink", sinkKind).Msg("Failed to set up sink")
 	}
+	defer sink.Close()
+
+	// 5. Setup graceful shutdown on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	limiter := rate.NewLimiter(rate.Limit(rps), 1)
+	inflight := make(chan struct{}, maxInflight)
+	jobs := make(chan dispatchJob, workers*2)
 
-	startIndex := (startPercent * totalItems) / 100
+	// 6. Kick off the dispatcher appropriate to the selected source.
+	if feedURL != "" {
+		log.Info().Str("feed", feedURL).Dur("poll", pollInterval).Msg("Starting news processor in live feed mode")
+		go dispatchFeed(ctx, feedURL, pollInterval, limiter, jobs)
+	} else {
+		newsData, err := loadNewsData(filename)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", filename).Msg("Error loading news data")
+		}
+		totalItems := len(newsData.Items)
+		if totalItems == 0 {
+			log.Fatal().Str("file", filename).Msg("No news items found in file")
+		}
 
-	// 6. Setup HTTP Client
-	httpClient := &http.Client{
-		Timeout: 10 * time.Second,
+		if retryFailed {
+			failed := checkpoint.failedKeys()
+			log.Info().Int("failed_items", len(failed)).Msg("Starting news processor in retry-failed mode")
+			go dispatchFailed(ctx, newsData, failed, limiter, jobs)
+		} else {
+			startIndex := (startPercent * totalItems) / 100
+			wrapCount := 0
+			resumed := resume && checkpoint.existed && checkpoint.data.LastIndex >= 0
+			if resume && !checkpoint.existed {
+				log.Info().Str("state", statePath).Msg("No prior checkpoint found, falling back to -start")
+			} else if resume && checkpoint.data.LastIndex < 0 {
+				log.Info().Str("state", statePath).Msg("Checkpoint has no confirmed sends yet, falling back to -start")
+			}
+			if resumed {
+				startIndex = (checkpoint.data.LastIndex + 1) % totalItems
+				wrapCount = checkpoint.data.WrapCount
+			}
+
+			log.Info().
+				Int("total_items", totalItems).
+				Str("feed_title", newsData.Feed.Title).
+				Int("start_index", startIndex).
+				Bool("resumed", resumed).
+				Int("workers", workers).
+				Float64("rps", rps).
+				Int("max_inflight", maxInflight).
+				Msg("Starting news processor in replay mode")
+
+			go dispatchReplay(ctx, newsData, startIndex, wrapCount, delay, limiter, jobs)
+		}
 	}
 
-	log.Info().
-		Int("total_items", totalItems).
-		Str("feed_title", newsData.Feed.Title).
-		Int("start_index", startIndex).
-		Int("delay_ms", delay).
-		Msg("Starting news processor")
+	// 7. Worker pool: each worker pulls jobs and POSTs them, bounded by max-inflight.
+	// sendCtx is deliberately not ctx: ctx cancels on SIGINT/SIGTERM to stop
+	// accepting new work, but a send already in flight should be allowed to
+	// finish rather than have its HTTP request aborted mid-flight. sendCtx
+	// only gets cancelled if shutdown-grace elapses before the drain below
+	// completes, as a backstop against a send that never returns.
+	sendCtx, cancelSend := context.WithCancel(context.Background())
+	defer cancelSend()
+	drained := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-drained:
+			return
+		}
+		select {
+		case <-time.After(shutdownGrace):
+			log.Warn().Dur("grace", shutdownGrace).Msg("Shutdown grace period elapsed, aborting in-flight sends")
+			cancelSend()
+		case <-drained:
+		}
+	}()
 
-	currentIndex := startIndex
-	itemCount := 0
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			for job := range jobs {
+				select {
+				case inflight <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				sendItem(sendCtx, sink, job, workerID, checkpoint)
+				<-inflight
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(drained)
+	if err := checkpoint.flush(); err != nil {
+		log.Error().Err(err).Str("path", statePath).Msg("Failed to flush checkpoint on shutdown")
+	}
+	log.Info().Msg("Shutdown complete, all in-flight requests drained")
+}
 
-	// 7. Process Loop
+// dispatchReplay walks the (wrapping) item list loaded from -file, starting
+// at startIndex/startWrap (as computed from -start or resumed from -state),
+// and feeds the jobs channel, closing it once the context is cancelled. The
+// replay cursor in the checkpoint is advanced by sendItem on confirmed
+// success, not here, since dispatch order can run ahead of completion.
+func dispatchReplay(ctx context.Context, newsData *NewsData, startIndex, startWrap, delayMs int, limiter *rate.Limiter, jobs chan<- dispatchJob) {
+	defer close(jobs)
+	totalItems := len(newsData.Items)
+	currentIndex := startIndex
+	wrapCount := startWrap
 	for {
-		// Get a copy of the item so we can modify it safely without changing the original slice
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err := limiter.Wait(ctx); err != nil {
+			return
+		}
+
 		item := newsData.Items[currentIndex]
-		itemCount++
+		job := dispatchJob{index: currentIndex, wrap: wrapCount, key: replayKey(currentIndex, item), item: item}
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return
+		}
 
-		// --- DATE CONVERSION LOGIC ---
-		// Parse from RSS format (RFC1123): "Fri, 30 Jan 2026 08:28:27 GMT"
-		parsedTime, err := time.Parse(time.RFC1123, item.PubDate)
-		if err != nil {
-			log.Warn().Err(err).Str("original_date", item.PubDate).Msg("Could not parse PubDate, keeping original")
-		} else {
-			// Convert to RFC3339Nano
-			item.PubDate = parsedTime.Format(time.RFC3339Nano)
+		currentIndex++
+		if currentIndex >= totalItems {
+			currentIndex = 0
+			wrapCount++
+			log.Info().Int("wrap_count", wrapCount).Msg("Reached end of list, wrapping back to beginning")
+		}
+
+		if delayMs > 0 {
+			select {
+			case <-time.After(time.Duration(delayMs) * time.Millisecond):
+			case <-ctx.Done():
+				return
+			}
 		}
-		// -----------------------------
+	}
+}
 
-		// Create a trimmed version of the data for the payload
-		payloadData := struct {
-			Title    string   `json:"title"`
-			Link     string   `json:"link"`
-			PubDate  string   `json:"pubDate"`
-			Disaster bool     `json:"disaster"`
-			Location Location `json:"location"`
-		}{
-			Title:    item.Title,
-			Link:     item.Link,
-			PubDate:  item.PubDate,
-			Disaster: item.Disaster,
-			Location: item.Location,
+// dispatchFailed replays only the items whose checkpoint key is in failed,
+// making a single pass with no wraparound.
+func dispatchFailed(ctx context.Context, newsData *NewsData, failed map[string]struct{}, limiter *rate.Limiter, jobs chan<- dispatchJob) {
+	defer close(jobs)
+	for i, item := range newsData.Items {
+		key := replayKey(i, item)
+		if _, ok := failed[key]; !ok {
+			continue
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			return
+		}
+		select {
+		case jobs <- dispatchJob{index: i, key: key, item: item}:
+		case <-ctx.Done():
+			return
 		}
+	}
+}
 
-		// Serialize the simplified payload
-		payload, err := json.Marshal(payloadData)
+// replayKey returns the checkpoint key for a -file item: its GUID/Link if
+// set, otherwise a positional fallback since replay dumps aren't guaranteed
+// to carry a GUID.
+func replayKey(index int, item NewsItem) string {
+	if key := itemKey(item); key != "" {
+		return key
+	}
+	return fmt.Sprintf("idx:%d", index)
+}
 
-		// Debug print to verify only title, link, and pubDate are present
-		println(string(payload))
+// dispatchFeed fetches feedURL, dispatches any not-yet-seen entries, and,
+// if pollInterval > 0, repeats on that cadence until the context is
+// cancelled. Entries are deduped by GUID/Link so a refresh only sends new
+// items. A pollInterval of 0 fetches and dispatches once, then returns.
+func dispatchFeed(ctx context.Context, feedURL string, pollInterval time.Duration, limiter *rate.Limiter, jobs chan<- dispatchJob) {
+	defer close(jobs)
+	seen := make(map[string]struct{})
+	dispatched := 0
 
+	poll := func() {
+		newsData, err := fetchFeed(ctx, feedURL)
 		if err != nil {
-			log.Error().Err(err).Str("title", item.Title).Msg("Failed to marshal item")
-		} else {
-			// Create Request
-			req, err := http.NewRequest("POST", targetURL, bytes.NewBuffer(payload))
-			if err != nil {
-				log.Error().Err(err).Msg("Failed to create request")
-			} else {
-				req.Header.Set("Content-Type", "application/json")
-
-				// Send Request
-				resp, err := httpClient.Do(req)
-				if err != nil {
-					log.Error().Err(err).Str("url", targetURL).Msg("Failed to send request")
-				} else {
-					// Handle Response
-					logger := log.Info()
-					if resp.StatusCode >= 400 {
-						logger = log.Warn()
-					}
-
-					logger.
-						Int("status", resp.StatusCode).
-						Str("title", item.Title).
-						Int("index", currentIndex).
-						Str("pub_date", item.PubDate).
-						Msg("Sent news item")
-
-					resp.Body.Close()
-				}
+			log.Error().Err(err).Str("feed", feedURL).Msg("Failed to fetch feed")
+			return
+		}
+		for _, item := range newsData.Items {
+			key := itemKey(item)
+			if key == "" {
+				log.Warn().Str("title", item.Title).Msg("Skipping feed entry with no GUID, Link, title, or pubDate to key on")
+				continue
+			}
+			if item.GUID == "" && item.Link == "" {
+				log.Warn().Str("title", item.Title).Msg("Feed entry has no GUID/Link, falling back to title+pubDate for dedup")
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if err := limiter.Wait(ctx); err != nil {
+				return
+			}
+			select {
+			case jobs <- dispatchJob{index: dispatched, key: key, item: item}:
+				dispatched++
+			case <-ctx.Done():
+				return
 			}
 		}
+	}
 
-		// Move to next item
-		currentIndex++
-		if currentIndex >= totalItems {
-			currentIndex = 0
-			log.Info().Msg("Reached end of list, wrapping back to beginning")
+	poll()
+	if pollInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			poll()
 		}
+	}
+}
 
-		time.Sleep(time.Duration(delay) * time.Millisecond)
+// sendItem normalizes an item's PubDate, builds the trimmed payload, hands
+// it to the configured Sink, logs the outcome, and records it in the
+// checkpoint.
+func sendItem(ctx context.Context, sink Sink, job dispatchJob, workerID int, checkpoint *checkpointStore) {
+	item := job.item
+
+	// --- DATE CONVERSION LOGIC ---
+	parsedTime, err := parsePubDate(item.PubDate)
+	if err != nil {
+		log.Warn().Err(err).Str("original_date", item.PubDate).Msg("Could not parse PubDate, keeping original")
+	} else {
+		// Convert to RFC3339Nano
+		item.PubDate = parsedTime.Format(time.RFC3339Nano)
 	}
+	// -----------------------------
+
+	// Create a trimmed version of the data for the payload
+	payloadData := struct {
+		Title    string   `json:"title"`
+		Link     string   `json:"link"`
+		PubDate  string   `json:"pubDate"`
+		Disaster bool     `json:"disaster"`
+		Location Location `json:"location"`
+	}{
+		Title:    item.Title,
+		Link:     item.Link,
+		PubDate:  item.PubDate,
+		Disaster: item.Disaster,
+		Location: item.Location,
+	}
+
+	// Serialize the simplified payload
+	payload, err := json.Marshal(payloadData)
+	if err != nil {
+		log.Error().Err(err).Str("title", item.Title).Msg("Failed to marshal item")
+		checkpoint.recordStatus(job.key, job.index, job.wrap, ItemStatus{Success: false, Error: err.Error(), SentAt: time.Now()})
+		return
+	}
+
+	// Debug print to verify only title, link, and pubDate are present
+	println(string(payload))
+
+	if err := sink.Send(ctx, payload); err != nil {
+		log.Warn().Err(err).Str("title", item.Title).Int("index", job.index).Msg("Failed to send news item")
+		status := ItemStatus{Success: false, Error: err.Error(), SentAt: time.Now()}
+		var httpErr *HTTPStatusError
+		if errors.As(err, &httpErr) {
+			status.HTTPStatus = httpErr.StatusCode
+		}
+		checkpoint.recordStatus(job.key, job.index, job.wrap, status)
+		return
+	}
+
+	log.Info().
+		Str("title", item.Title).
+		Int("index", job.index).
+		Int("worker", workerID).
+		Str("pub_date", item.PubDate).
+		Msg("Sent news item")
+
+	checkpoint.recordStatus(job.key, job.index, job.wrap, ItemStatus{Success: true, SentAt: time.Now()})
 }
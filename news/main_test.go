@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDateParts(t *testing.T) {
+	cases := []struct {
+		name      string
+		a, b, c   string
+		wantYear  int
+		wantMonth time.Month
+		wantDay   int
+		wantOK    bool
+	}{
+		{name: "iso order", a: "2024", b: "03", c: "17", wantYear: 2024, wantMonth: time.March, wantDay: 17, wantOK: true},
+		{name: "us order", a: "03", b: "17", c: "2024", wantYear: 2024, wantMonth: time.March, wantDay: 17, wantOK: true},
+		{name: "month name", a: "17", b: "Mar", c: "2024", wantYear: 2024, wantMonth: time.March, wantDay: 17, wantOK: true},
+		{name: "full month name", a: "March", b: "17", c: "2024", wantYear: 2024, wantMonth: time.March, wantDay: 17, wantOK: true},
+		{name: "two-digit year without month", wantOK: false, a: "17", b: "31", c: "12"},
+		{name: "day out of range", a: "2024", b: "03", c: "45", wantOK: false},
+		{name: "not a date at all", a: "foo", b: "bar", c: "baz", wantOK: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			year, month, day, ok := resolveDateParts(tc.a, tc.b, tc.c)
+			if ok != tc.wantOK {
+				t.Fatalf("resolveDateParts(%q, %q, %q) ok = %v, want %v", tc.a, tc.b, tc.c, ok, tc.wantOK)
+			}
+			if !tc.wantOK {
+				return
+			}
+			if year != tc.wantYear || month != tc.wantMonth || day != tc.wantDay {
+				t.Fatalf("resolveDateParts(%q, %q, %q) = (%d, %s, %d), want (%d, %s, %d)",
+					tc.a, tc.b, tc.c, year, month, day, tc.wantYear, tc.wantMonth, tc.wantDay)
+			}
+		})
+	}
+}
+
+func TestParsePermissive(t *testing.T) {
+	got, err := parsePermissive("17 March 2024 08:15:30 +0200")
+	if err != nil {
+		t.Fatalf("parsePermissive returned error: %v", err)
+	}
+	want := time.Date(2024, time.March, 17, 8, 15, 30, 0, time.FixedZone("", 2*3600))
+	if !got.Equal(want) {
+		t.Fatalf("parsePermissive = %v, want %v", got, want)
+	}
+}
+
+func newTestCheckpoint() *checkpointStore {
+	return &checkpointStore{
+		path: "unused", // non-empty so recordStatus doesn't early-return
+		data: Checkpoint{LastIndex: -1, Items: make(map[string]ItemStatus)},
+	}
+}
+
+func TestRecordStatusAdvancesOnlyForward(t *testing.T) {
+	cs := newTestCheckpoint()
+
+	// A later dispatch (index 5) confirms before an earlier one (index 3).
+	cs.recordStatus("k5", 5, 0, ItemStatus{Success: true})
+	if cs.data.LastIndex != 5 {
+		t.Fatalf("LastIndex = %d, want 5", cs.data.LastIndex)
+	}
+	cs.recordStatus("k3", 3, 0, ItemStatus{Success: true})
+	if cs.data.LastIndex != 5 {
+		t.Fatalf("a stale confirmation regressed LastIndex to %d, want 5", cs.data.LastIndex)
+	}
+
+	// A failure never advances the cursor, even past the current LastIndex.
+	cs.recordStatus("k9", 9, 0, ItemStatus{Success: false, Error: "boom"})
+	if cs.data.LastIndex != 5 {
+		t.Fatalf("a failed send advanced LastIndex to %d, want 5", cs.data.LastIndex)
+	}
+
+	// A confirmation in a later wrap always beats any index from an earlier one.
+	cs.recordStatus("k0", 0, 1, ItemStatus{Success: true})
+	if cs.data.LastIndex != 0 || cs.data.WrapCount != 1 {
+		t.Fatalf("LastIndex/WrapCount = %d/%d, want 0/1", cs.data.LastIndex, cs.data.WrapCount)
+	}
+}
+
+func TestRecordStatusNeverConfirmedStaysSentinel(t *testing.T) {
+	cs := newTestCheckpoint()
+	cs.recordStatus("k0", 0, 0, ItemStatus{Success: false, Error: "boom"})
+	if cs.data.LastIndex != -1 {
+		t.Fatalf("LastIndex = %d, want -1 (nothing confirmed yet)", cs.data.LastIndex)
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
+)
+
+// Sink is the destination a news item payload is delivered to. It lets the
+// same dispatch/worker pipeline feed an HTTP endpoint, a Kafka topic, an
+// NDJSON file, or stdout, without forking the binary per use case.
+type Sink interface {
+	Send(ctx context.Context, payload []byte) error
+	Close() error
+}
+
+// HTTPStatusError reports a non-2xx response from a Sink backed by HTTP, so
+// callers can recover the status code via errors.As without the Sink
+// interface itself needing to know about HTTP.
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("http status %d", e.StatusCode)
+}
+
+// newSink builds the Sink selected by -sink, along with any sink-specific
+// flags.
+func newSink(kind, outFile, kafkaBrokers, kafkaTopic string, retryCfg retryConfig, breakerThreshold int, breakerCooldown time.Duration) (Sink, error) {
+	switch kind {
+	case "http":
+		return newHTTPSink(retryCfg, breakerThreshold, breakerCooldown), nil
+	case "file":
+		return newFileSink(outFile)
+	case "stdout":
+		return newStdoutSink(), nil
+	case "kafka":
+		return newKafkaSink(kafkaBrokers, kafkaTopic)
+	default:
+		return nil, fmt.Errorf("unknown sink %q (want http, file, stdout, or kafka)", kind)
+	}
+}
+
+// httpSink POSTs payloads to targetURL, retrying transient failures and
+// tripping a circuit breaker after sustained failure.
+type httpSink struct {
+	client   *http.Client
+	retryCfg retryConfig
+	breaker  *circuitBreaker
+}
+
+func newHTTPSink(retryCfg retryConfig, breakerThreshold int, breakerCooldown time.Duration) *httpSink {
+	return &httpSink{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		retryCfg: retryCfg,
+		breaker:  newCircuitBreaker(breakerThreshold, breakerCooldown),
+	}
+}
+
+func (s *httpSink) Send(ctx context.Context, payload []byte) error {
+	if err := s.breaker.waitUntilOpen(ctx); err != nil {
+		return err
+	}
+
+	buildReq := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", targetURL, bytes.NewBuffer(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}
+
+	resp, err := doWithRetry(ctx, s.client, buildReq, s.retryCfg)
+	if err != nil {
+		s.breaker.recordFailure()
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		s.breaker.recordFailure()
+		return &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	s.breaker.recordSuccess()
+	return nil
+}
+
+func (s *httpSink) Close() error { return nil }
+
+// fileSink appends each payload as one NDJSON line, for offline analysis.
+type fileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func newFileSink(path string) (*fileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("-out-file is required for -sink file")
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening -out-file: %w", err)
+	}
+	return &fileSink{f: f}, nil
+}
+
+func (s *fileSink) Send(ctx context.Context, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.f.Write(append(payload, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	return s.f.Close()
+}
+
+// stdoutSink writes each payload as a line to stdout.
+type stdoutSink struct {
+	mu sync.Mutex
+}
+
+func newStdoutSink() *stdoutSink {
+	return &stdoutSink{}
+}
+
+func (s *stdoutSink) Send(ctx context.Context, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := os.Stdout.Write(append(payload, '\n'))
+	return err
+}
+
+func (s *stdoutSink) Close() error { return nil }
+
+// kafkaSink publishes each payload as a message on a Kafka topic.
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func newKafkaSink(brokers, topic string) (*kafkaSink, error) {
+	if brokers == "" || topic == "" {
+		return nil, fmt.Errorf("-kafka-brokers and -kafka-topic are required for -sink kafka")
+	}
+	return &kafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(strings.Split(brokers, ",")...),
+			Topic:    topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}, nil
+}
+
+func (s *kafkaSink) Send(ctx context.Context, payload []byte) error {
+	return s.writer.WriteMessages(ctx, kafka.Message{Value: payload})
+}
+
+func (s *kafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+// retryConfig bounds the retry/backoff policy used by doWithRetry.
+type retryConfig struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// isRetryableStatus reports whether an HTTP status code warrants a retry:
+// server errors and rate limiting, but not client errors.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfterDelay parses a Retry-After header (seconds or HTTP-date form),
+// returning ok=false if absent or unparseable.
+func retryAfterDelay(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// backoffDelay computes the jittered exponential backoff for the given
+// retry attempt (0-indexed), capped at cfg.MaxDelay.
+func backoffDelay(cfg retryConfig, attempt int) time.Duration {
+	delay := cfg.BaseDelay * time.Duration(1<<uint(attempt))
+	if cfg.MaxDelay > 0 && delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay/2 + jitter
+}
+
+// doWithRetry sends a request built fresh by buildReq on each attempt
+// (since a consumed request body can't be reused), retrying network errors
+// and retryable status codes up to cfg.MaxRetries times with exponential
+// backoff, honoring any Retry-After header the server sends.
+func doWithRetry(ctx context.Context, httpClient *http.Client, buildReq func() (*http.Request, error), cfg retryConfig) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+		req, err := buildReq()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := httpClient.Do(req)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("retryable status %d", resp.StatusCode)
+		}
+
+		if attempt == cfg.MaxRetries {
+			if err == nil {
+				return resp, nil
+			}
+			break
+		}
+
+		delay := backoffDelay(cfg, attempt)
+		if resp != nil {
+			if after, ok := retryAfterDelay(resp); ok {
+				delay = after
+			}
+			resp.Body.Close()
+		}
+
+		log.Warn().Err(lastErr).Int("attempt", attempt+1).Dur("backoff", delay).Msg("Retrying request")
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, lastErr
+}
+
+// circuitBreaker pauses sends for a cooldown window once consecutive
+// failures reach threshold, to avoid hammering a target that's already down.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	threshold   int
+	cooldown    time.Duration
+	consecutive int
+	openUntil   time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// waitUntilOpen blocks the caller until the breaker's cooldown has elapsed
+// (or ctx is cancelled), so a tripped breaker defers sends instead of
+// letting the dispatcher keep producing jobs that fail instantly.
+func (cb *circuitBreaker) waitUntilOpen(ctx context.Context) error {
+	if cb.threshold <= 0 {
+		return nil
+	}
+	for {
+		cb.mu.Lock()
+		remaining := time.Until(cb.openUntil)
+		cb.mu.Unlock()
+		if remaining <= 0 {
+			return nil
+		}
+		log.Warn().Dur("remaining", remaining).Msg("Circuit breaker open, pausing send")
+		select {
+		case <-time.After(remaining):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// recordSuccess resets the consecutive-failure count.
+func (cb *circuitBreaker) recordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.consecutive = 0
+}
+
+// recordFailure bumps the consecutive-failure count and, once it reaches
+// threshold, opens the breaker for cooldown.
+func (cb *circuitBreaker) recordFailure() {
+	if cb.threshold <= 0 {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.consecutive++
+	if cb.consecutive >= cb.threshold {
+		cb.openUntil = time.Now().Add(cb.cooldown)
+		cb.consecutive = 0
+		log.Warn().Int("threshold", cb.threshold).Dur("cooldown", cb.cooldown).Msg("Circuit breaker tripped, pausing sends")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelayCapsAtMaxDelay(t *testing.T) {
+	cfg := retryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: 500 * time.Millisecond}
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoffDelay(cfg, attempt)
+		if d > cfg.MaxDelay {
+			t.Fatalf("backoffDelay(attempt=%d) = %v, exceeds MaxDelay %v", attempt, d, cfg.MaxDelay)
+		}
+		if d < 0 {
+			t.Fatalf("backoffDelay(attempt=%d) = %v, want non-negative", attempt, d)
+		}
+	}
+}
+
+func TestBackoffDelayGrowsWithAttempt(t *testing.T) {
+	cfg := retryConfig{BaseDelay: 50 * time.Millisecond, MaxDelay: time.Hour}
+	// The jittered delay for a later attempt should never fall below the
+	// smallest possible jittered delay for an earlier one, since the
+	// underlying exponential term only grows.
+	minPossible := func(cfg retryConfig, attempt int) time.Duration {
+		delay := cfg.BaseDelay * time.Duration(1<<uint(attempt))
+		return delay / 2
+	}
+	if got, want := minPossible(cfg, 3), minPossible(cfg, 0); got <= want {
+		t.Fatalf("minimum possible delay did not grow with attempt: attempt 3 min %v <= attempt 0 min %v", got, want)
+	}
+}